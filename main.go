@@ -1,28 +1,127 @@
 package main
 
 import (
+  "bufio"
+  "bytes"
+  "context"
   "fmt"
   "io"
+  "net"
+  "os"
   "sync"
+  "unicode/utf8"
 )
 
 const (
-  smallBufferSize = 64
-  maxInt = int(^uint(0) >> 1)
   readSize = 32 * 1024
+  chunkSize = 32 * 1024
 )
 
+// chunkPool hands out fixed-size []byte backing arrays for memChunk, so
+// buffers can reuse memory across many MultiReadBuffer instances instead of
+// each growing and discarding its own backing array.
+var chunkPool = sync.Pool{
+  New: func() interface{} {
+    buf := make([]byte, chunkSize)
+    return &buf
+  },
+}
+
+// memChunk is one fixed-size segment of a MultiReadBuffer's in-memory
+// storage. buf is always a full chunkSize backing array drawn from
+// chunkPool; len tracks how much of it has been written.
+type memChunk struct {
+  buf *[]byte
+  len int
+}
+
+func newMemChunk() *memChunk {
+  return &memChunk{buf: chunkPool.Get().(*[]byte)}
+}
+
 type MultiReadBuffer struct {
-  buf []byte
-  offset int
+  chunks []*memChunk
+  readChunk int // index into chunks the read cursor is in
+  readOff int // read offset within chunks[readChunk]
+  memLen int64 // total bytes written across all chunks
   mu sync.Mutex
+
+  maxMemBytes int64 // 0 means no in-memory cap, i.e. never spill
+  maxTotalBytes int64 // 0 means no total size cap
+  spillDir string // dir for the spill file; "" uses the OS default temp dir
+
+  spill *os.File
+  spillSize int64 // bytes written to the spill file so far
+  spillOffset int64 // read cursor within the spill file
+
+  isPipe bool // true for buffers created via NewPipeBuffer
+  pipeCap int // max unread, buffered bytes before Write blocks, see NewPipeBuffer
+  cond *sync.Cond // signaled on every pipe state change; guards on b.mu
+  writeClosed bool // true once CloseWrite/CloseWriteWithError has been called
+  writeErr error // error to surface to readers once drained, if any
+  reclaimedBytes int64 // bytes permanently dropped from the front by reclaimConsumedChunksLocked
+}
+
+// Option configures a MultiReadBuffer created via NewMultiReadBuffer.
+type Option func(*MultiReadBuffer)
+
+// WithMaxMemBytes caps the number of bytes kept in memory before the
+// buffer starts spilling further writes to disk.
+func WithMaxMemBytes(n int64) Option {
+  return func(b *MultiReadBuffer) { b.maxMemBytes = n }
+}
+
+// WithMaxTotalBytes caps the combined in-memory and spilled size of the
+// buffer; Write returns an error once this cap would be exceeded.
+func WithMaxTotalBytes(n int64) Option {
+  return func(b *MultiReadBuffer) { b.maxTotalBytes = n }
+}
+
+// WithSpillDir sets the directory in which the spill file is created via
+// os.CreateTemp. An empty dir (the default) uses the OS temp dir.
+func WithSpillDir(dir string) Option {
+  return func(b *MultiReadBuffer) { b.spillDir = dir }
+}
+
+// NewMultiReadBuffer creates a MultiReadBuffer with the given options. The
+// zero value MultiReadBuffer{} remains valid and behaves as an unbounded,
+// memory-only buffer.
+func NewMultiReadBuffer(opts ...Option) *MultiReadBuffer {
+  b := &MultiReadBuffer{}
+  for _, opt := range opts {
+    opt(b)
+  }
+  return b
+}
+
+// NewPipeBuffer creates a MultiReadBuffer in bounded producer/consumer pipe
+// mode: Write blocks while the unread, buffered byte count would exceed
+// cap, and Read blocks while empty until data is written or CloseWrite (or
+// CloseWriteWithError) is called. cap <= 0 is treated as 1.
+func NewPipeBuffer(cap int) *MultiReadBuffer {
+  if cap <= 0 {
+    cap = 1
+  }
+  b := &MultiReadBuffer{isPipe: true, pipeCap: cap}
+  b.cond = sync.NewCond(&b.mu)
+  return b
+}
+
+// bytesLocked concatenates every chunk's written bytes. Assumes the caller
+// holds the lock.
+func (b *MultiReadBuffer) bytesLocked() []byte {
+  out := make([]byte, 0, b.memLen)
+  for _, c := range b.chunks {
+    out = append(out, (*c.buf)[:c.len]...)
+  }
+  return out
 }
 
-func (b *MultiReadBuffer) Bytes() []byte { 
+func (b *MultiReadBuffer) Bytes() []byte {
   b.mu.Lock()
 	defer b.mu.Unlock()
-  // no offset advancing!
-  return b.buf 
+  // no offset advancing! note: excludes any bytes spilled to disk.
+  return b.bytesLocked()
 }
 
 func (b *MultiReadBuffer) String() string {
@@ -31,65 +130,396 @@ func (b *MultiReadBuffer) String() string {
 	}
   b.mu.Lock()
 	defer b.mu.Unlock()
-  // no offset advancing!
-	return string(b.buf)
+  // no offset advancing! note: excludes any bytes spilled to disk.
+	return string(b.bytesLocked())
 }
 
-func (b *MultiReadBuffer) Len() int { 
+// memReadLocked returns how many of the written in-memory bytes have
+// already been consumed by the read cursor. Assumes the caller holds the
+// lock.
+func (b *MultiReadBuffer) memReadLocked() int64 {
+  var read int64
+  for i := 0; i < b.readChunk && i < len(b.chunks); i++ {
+    read += int64(b.chunks[i].len)
+  }
+  if b.readChunk < len(b.chunks) {
+    read += int64(b.readOff)
+  }
+  return read
+}
+
+func (b *MultiReadBuffer) Len() int {
   b.mu.Lock()
 	defer b.mu.Unlock()
-  return len(b.buf) - b.offset 
+  return int(b.memLen-b.memReadLocked()) + int(b.spillSize-b.spillOffset)
 }
 
+// Reset seeks both the in-memory and spilled segments back to offset 0, so
+// the previously written content can be read again (write-once, read-many).
 func (b *MultiReadBuffer) Reset() {
   b.mu.Lock()
 	defer b.mu.Unlock()
-	b.offset = 0
+	b.readChunk, b.readOff = 0, 0
+  b.spillOffset = 0
+}
+
+// Size returns the total number of bytes ever written to the buffer,
+// regardless of how much of it has already been read.
+func (b *MultiReadBuffer) Size() int64 {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  return b.reclaimedBytes + b.memLen + b.spillSize
+}
+
+// setPosLocked repositions the buffer's own read cursor to the absolute
+// byte offset pos. Assumes the caller holds the lock and 0 <= pos <=
+// b.memLen+b.spillSize.
+func (b *MultiReadBuffer) setPosLocked(pos int64) {
+  if pos > b.memLen {
+    b.readChunk, b.readOff = len(b.chunks), 0
+    b.spillOffset = pos - b.memLen
+    return
+  }
+  idx, off := 0, pos
+  // Stop at the last chunk, as readMemLocked does: it may still grow.
+  for idx < len(b.chunks)-1 && off >= int64(b.chunks[idx].len) {
+    off -= int64(b.chunks[idx].len)
+    idx++
+  }
+  b.readChunk, b.readOff = idx, int(off)
+  b.spillOffset = 0
+}
+
+// Seek repositions the buffer's own read cursor, the one used by Read,
+// ReadString and WriteTo. It does not affect the write (append) position,
+// nor readers created with NewReader.
+func (b *MultiReadBuffer) Seek(offset int64, whence int) (int64, error) {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  size := b.memLen + b.spillSize
+  var abs int64
+  switch whence {
+  case io.SeekStart:
+    abs = offset
+  case io.SeekCurrent:
+    abs = b.memReadLocked() + b.spillOffset + offset
+  case io.SeekEnd:
+    abs = size + offset
+  default:
+    return 0, fmt.Errorf("MultiReadBuffer.Seek: invalid whence %d", whence)
+  }
+  if abs < 0 {
+    return 0, fmt.Errorf("MultiReadBuffer.Seek: negative position %d", abs)
+  }
+  if abs > size {
+    abs = size
+  }
+  b.setPosLocked(abs)
+  return abs, nil
 }
 
 func (b *MultiReadBuffer) Clear() {
   b.mu.Lock()
 	defer b.mu.Unlock()
-	b.offset = 0
-  b.buf = b.buf[:0]
+  b.releaseChunksLocked()
+  b.removeSpillLocked()
+}
+
+// Release returns every chunk's backing array to the shared pool and
+// empties the in-memory part of the buffer, freeing the caller to reuse
+// the memory elsewhere. Bytes already spilled to disk, and the spill file,
+// are kept; Clear or Close removes them.
+func (b *MultiReadBuffer) Release() {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  b.releaseChunksLocked()
+}
+
+// releaseChunksLocked puts every chunk back in chunkPool. Assumes the
+// caller holds the lock.
+func (b *MultiReadBuffer) releaseChunksLocked() {
+  for _, c := range b.chunks {
+    chunkPool.Put(c.buf)
+  }
+  b.chunks = nil
+  b.readChunk, b.readOff = 0, 0
+  b.memLen = 0
+}
+
+// Close removes any spill file created by the buffer. It is safe to call
+// more than once, and on a buffer that never spilled to disk.
+func (b *MultiReadBuffer) Close() error {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  return b.removeSpillLocked()
 }
 
-func (b *MultiReadBuffer) grow(n int) (int, error) {
-  // assuming the caller has obtained the lock!
-  if b.buf == nil { // new
-    ll := 2 * n
-    if ll < smallBufferSize {
-      ll = smallBufferSize
+// removeSpillLocked closes and removes the spill file, if any. Assumes the
+// caller holds the lock.
+func (b *MultiReadBuffer) removeSpillLocked() error {
+  if b.spill == nil {
+    return nil
+  }
+  name := b.spill.Name()
+  err := b.spill.Close()
+  b.spill = nil
+  b.spillSize, b.spillOffset = 0, 0
+  if rmErr := os.Remove(name); rmErr != nil && err == nil {
+    err = rmErr
+  }
+  return err
+}
+
+// appendMemLocked appends p into the chunk chain, allocating new chunks
+// from chunkPool as the tail chunk fills up. Assumes the caller holds the
+// lock.
+func (b *MultiReadBuffer) appendMemLocked(p []byte) int {
+  written := 0
+  for len(p) > 0 {
+    if len(b.chunks) == 0 || b.chunks[len(b.chunks)-1].len == chunkSize {
+      b.chunks = append(b.chunks, newMemChunk())
     }
-		b.buf = make([]byte, n, ll)
-		return 0, nil
-	}
-  l, c := len(b.buf), cap(b.buf)
-  if n <= c-l { // has room
-		b.buf = b.buf[:l+n] // modify slice length
-		return l, nil
-	}
-  ll := l + n + c // fill n plus extra c
-  if ll > maxInt {
-    if l+n > maxInt {
-      return -1, fmt.Errorf("too large (current %d, new %d, max %d)", l, n, maxInt)
+    tail := b.chunks[len(b.chunks)-1]
+    m := copy((*tail.buf)[tail.len:], p)
+    tail.len += m
+    p = p[m:]
+    written += m
+  }
+  b.memLen += int64(written)
+  return written
+}
+
+// writeLocked appends p, spilling to disk past maxMemBytes, and failing
+// once maxTotalBytes would be exceeded. In pipe mode it instead blocks
+// until there's room, per pipeWriteLocked. Assumes the caller holds the
+// lock.
+func (b *MultiReadBuffer) writeLocked(p []byte) (n int, err error) {
+  if b.isPipe {
+    return b.pipeWriteLocked(nil, p)
+  }
+  return b.boundedWriteLocked(p)
+}
+
+// boundedWriteLocked is the non-pipe write path: spill to disk past
+// maxMemBytes, failing once maxTotalBytes would be exceeded. Assumes the
+// caller holds the lock.
+func (b *MultiReadBuffer) boundedWriteLocked(p []byte) (n int, err error) {
+  total := b.memLen + b.spillSize
+  if b.maxTotalBytes > 0 && total+int64(len(p)) > b.maxTotalBytes {
+    return 0, fmt.Errorf("write of %d bytes exceeds max total bytes %d (already have %d)", len(p), b.maxTotalBytes, total)
+  }
+  if b.maxMemBytes <= 0 || b.memLen < b.maxMemBytes {
+    room := len(p)
+    if b.maxMemBytes > 0 {
+      if avail := b.maxMemBytes - b.memLen; int64(room) > avail {
+        room = int(avail)
+      }
+    }
+    if room > 0 {
+      n += b.appendMemLocked(p[:room])
+      p = p[room:]
+    }
+  }
+  if len(p) > 0 {
+    m, err := b.spillWriteLocked(p)
+    n += m
+    if err != nil {
+      return n, err
+    }
+  }
+  return n, nil
+}
+
+// spillWriteLocked appends p to the spill file, creating it on first use.
+// Assumes the caller holds the lock.
+func (b *MultiReadBuffer) spillWriteLocked(p []byte) (int, error) {
+  if b.spill == nil {
+    f, err := os.CreateTemp(b.spillDir, "multireadbuffer-*.spill")
+    if err != nil {
+      return 0, fmt.Errorf("failed to create spill file: %w", err)
+    }
+    b.spill = f
+  }
+  m, err := b.spill.WriteAt(p, b.spillSize)
+  b.spillSize += int64(m)
+  if err != nil {
+    return m, fmt.Errorf("failed to write to spill file: %w", err)
+  }
+  if m != len(p) {
+    return m, io.ErrShortWrite
+  }
+  return m, nil
+}
+
+// bufferedLocked returns the number of unread bytes held in memory.
+// Assumes the caller holds the lock.
+func (b *MultiReadBuffer) bufferedLocked() int {
+  return int(b.memLen - b.memReadLocked())
+}
+
+// reclaimConsumedChunksLocked returns fully-read leading chunks to
+// chunkPool and drops them, so a long-lived pipe buffer's retained memory
+// tracks pipeCap rather than the total bytes ever written. It is only
+// safe for pipe buffers, which are drained once and never Reset to
+// replay earlier content; dropped bytes are tallied in reclaimedBytes so
+// a multiReader (readAt) can still detect and reject stale positions
+// rather than silently reading the wrong bytes. Assumes the caller holds
+// the lock.
+func (b *MultiReadBuffer) reclaimConsumedChunksLocked() {
+  if b.readChunk == 0 {
+    return
+  }
+  var consumed int64
+  for _, c := range b.chunks[:b.readChunk] {
+    consumed += int64(c.len)
+    chunkPool.Put(c.buf)
+  }
+  b.chunks = b.chunks[b.readChunk:]
+  b.readChunk = 0
+  b.memLen -= consumed
+  b.reclaimedBytes += consumed
+}
+
+// watchCtxLocked arranges for b.cond to be broadcast when ctx is done,
+// waking any goroutine parked in pipeWriteLocked/pipeReadLocked so it can
+// observe ctx.Err(). The returned func must be called (typically deferred)
+// once the wait loop returns, to stop the watcher. Assumes the caller
+// holds the lock; safe to call with a nil ctx.
+func (b *MultiReadBuffer) watchCtxLocked(ctx context.Context) func() {
+  if ctx == nil {
+    return func() {}
+  }
+  done := ctx.Done()
+  if done == nil {
+    return func() {}
+  }
+  stop := make(chan struct{})
+  go func() {
+    select {
+    case <-done:
+      // Take the lock so the broadcast cannot land before the caller has
+      // reached cond.Wait.
+      b.mu.Lock()
+      b.cond.Broadcast()
+      b.mu.Unlock()
+    case <-stop:
+    }
+  }()
+  return func() { close(stop) }
+}
+
+// pipeWriteLocked blocks while the unread, buffered byte count would
+// exceed pipeCap, writing as room becomes available, until all of p is
+// written, the write side is closed, or ctx (if non-nil) is done. Assumes
+// the caller holds the lock.
+func (b *MultiReadBuffer) pipeWriteLocked(ctx context.Context, p []byte) (n int, err error) {
+  var stop func()
+  defer func() {
+    if stop != nil {
+      stop()
+    }
+  }()
+  for len(p) > 0 {
+    if b.writeClosed {
+      return n, io.ErrClosedPipe
+    }
+    if ctx != nil {
+      if err := ctx.Err(); err != nil {
+        return n, err
+      }
+    }
+    avail := b.pipeCap - b.bufferedLocked()
+    if avail <= 0 {
+      if stop == nil {
+        stop = b.watchCtxLocked(ctx)
+      }
+      b.cond.Wait()
+      continue
+    }
+    room := len(p)
+    if room > avail {
+      room = avail
+    }
+    b.appendMemLocked(p[:room])
+    p = p[room:]
+    n += room
+    b.cond.Broadcast()
+  }
+  return n, nil
+}
+
+// pipeReadLocked blocks while empty until data is written, the write side
+// is closed, or ctx (if non-nil) is done. Assumes the caller holds the
+// lock.
+func (b *MultiReadBuffer) pipeReadLocked(ctx context.Context, p []byte) (n int, err error) {
+  var stop func()
+  defer func() {
+    if stop != nil {
+      stop()
     }
-    ll = maxInt
+  }()
+  for {
+    if n = b.readMemLocked(p); n > 0 {
+      b.reclaimConsumedChunksLocked()
+      b.cond.Broadcast() // there's room now, wake any blocked writer
+      return n, nil
+    }
+    if b.writeClosed {
+      if b.writeErr != nil {
+        return 0, b.writeErr
+      }
+      return 0, io.EOF
+    }
+    if ctx != nil {
+      if err := ctx.Err(); err != nil {
+        return 0, err
+      }
+    }
+    if stop == nil {
+      stop = b.watchCtxLocked(ctx)
+    }
+    b.cond.Wait()
   }
-  buf := make([]byte, l+n, ll)
-  copy(buf, b.buf) // copy does not adjust slice length
-  b.buf = buf
-  return l, nil
+}
+
+// CloseWrite marks the producer side done. Once the buffered data has been
+// drained, Read (and ReadContext) return io.EOF. It is idempotent.
+func (b *MultiReadBuffer) CloseWrite() error {
+  return b.CloseWriteWithError(nil)
+}
+
+// CloseWriteWithError is like CloseWrite, but err, if non-nil, is what Read
+// (and ReadContext) return once the buffered data has been drained,
+// instead of io.EOF.
+func (b *MultiReadBuffer) CloseWriteWithError(err error) error {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  if b.writeClosed {
+    return nil
+  }
+  b.writeClosed = true
+  b.writeErr = err
+  if b.cond != nil {
+    b.cond.Broadcast()
+  }
+  return nil
+}
+
+// WriteContext is like Write, but in pipe mode it aborts with ctx.Err()
+// if ctx is done before there is room to write p.
+func (b *MultiReadBuffer) WriteContext(ctx context.Context, p []byte) (n int, err error) {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  if !b.isPipe {
+    return b.writeLocked(p)
+  }
+  return b.pipeWriteLocked(ctx, p)
 }
 
 func (b *MultiReadBuffer) Write(p []byte) (n int, err error) {
   b.mu.Lock()
 	defer b.mu.Unlock()
-	m, err := b.grow(len(p))
-	if err != nil {
-		return 0, err
-	}
-	return copy(b.buf[m:], p), nil
+  return b.writeLocked(p)
 }
 
 func (b *MultiReadBuffer) WriteStringf(format string, args ...interface{}) (n int, err error) {
@@ -100,33 +530,201 @@ func (b *MultiReadBuffer) WriteStringf(format string, args ...interface{}) (n in
 func (b *MultiReadBuffer) WriteString(s string) (n int, err error) {
   b.mu.Lock()
 	defer b.mu.Unlock()
-  m, err := b.grow(len(s))
-	if err != nil {
-		return 0, err
-	}
-	return copy(b.buf[m:], s), nil
+  return b.writeLocked([]byte(s))
+}
+
+// WriteRune writes the UTF-8 encoding of r to the buffer.
+func (b *MultiReadBuffer) WriteRune(r rune) (n int, err error) {
+  var buf [utf8.UTFMax]byte
+  m := utf8.EncodeRune(buf[:], r)
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  return b.writeLocked(buf[:m])
+}
+
+// readMemLocked copies as much as fits of p from the chunk chain, advancing
+// the read cursor. Assumes the caller holds the lock.
+func (b *MultiReadBuffer) readMemLocked(p []byte) int {
+  n := 0
+  for n < len(p) && b.readChunk < len(b.chunks) {
+    c := b.chunks[b.readChunk]
+    if b.readOff >= c.len {
+      // Only skip past this chunk once a later one exists: the last chunk
+      // is still the active write target and may grow, so stop here
+      // instead of advancing past data that hasn't arrived yet.
+      if b.readChunk+1 >= len(b.chunks) {
+        break
+      }
+      b.readChunk++
+      b.readOff = 0
+      continue
+    }
+    m := copy(p[n:], (*c.buf)[b.readOff:c.len])
+    b.readOff += m
+    n += m
+  }
+  return n
 }
 
+// Read drains the in-memory head first, then the spilled tail. In pipe
+// mode it instead blocks until data arrives, per pipeReadLocked.
 func (b *MultiReadBuffer) Read(p []byte) (n int, err error) {
   b.mu.Lock()
 	defer b.mu.Unlock()
-	if len(b.buf) - b.offset == 0 { // no more to read
+  if b.isPipe {
+    return b.pipeReadLocked(nil, p)
+  }
+  return b.boundedReadLocked(p)
+}
+
+// boundedReadLocked is the non-pipe read path. Assumes the caller holds
+// the lock.
+func (b *MultiReadBuffer) boundedReadLocked(p []byte) (n int, err error) {
+  n = b.readMemLocked(p)
+  if n < len(p) {
+    if spillAvail := b.spillSize - b.spillOffset; spillAvail > 0 {
+      want := int64(len(p) - n)
+      if want > spillAvail {
+        want = spillAvail
+      }
+      m, e := b.spill.ReadAt(p[n:n+int(want)], b.spillOffset)
+      b.spillOffset += int64(m)
+      n += m
+      if e != nil && e != io.EOF {
+        return n, e
+      }
+    }
+  }
+  if n == 0 { // no more to read
 		return 0, io.EOF
 	}
-	n = copy(p, b.buf[b.offset:])
-	b.offset += n
 	return n, nil
 }
 
+// ReadContext is like Read, but in pipe mode it aborts with ctx.Err() if
+// ctx is done before data arrives.
+func (b *MultiReadBuffer) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  if !b.isPipe {
+    return b.boundedReadLocked(p)
+  }
+  return b.pipeReadLocked(ctx, p)
+}
+
+// ReadString reads and returns all remaining unread content, including
+// any bytes spilled to disk, advancing the buffer's own read cursor past
+// all of it.
 func (b *MultiReadBuffer) ReadString() string {
 	if b == nil { // Special case, useful in debugging.
 		return "<nil>"
 	}
   b.mu.Lock()
 	defer b.mu.Unlock()
-  s := string(b.buf[b.offset:])
-  b.offset += len(s)
-	return s
+  buf := make([]byte, int(b.memLen-b.memReadLocked())+int(b.spillSize-b.spillOffset))
+  n, _ := b.boundedReadLocked(buf)
+	return string(buf[:n])
+}
+
+// ReadBytes reads until the first occurrence of delim, advancing the
+// buffer's own read cursor in place, and returns the bytes read including
+// delim. In pipe mode it blocks while no delim is available, the way Read
+// does, and surfaces CloseWriteWithError's error in place of io.EOF.
+// Otherwise, if ReadBytes encounters an error before finding a delimiter,
+// it returns the data read so far along with the error, which is io.EOF
+// if the buffer is exhausted.
+func (b *MultiReadBuffer) ReadBytes(delim byte) ([]byte, error) {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  var out []byte
+  for {
+    for b.readChunk < len(b.chunks) {
+      c := b.chunks[b.readChunk]
+      if b.readOff >= c.len {
+        // As in readMemLocked, don't advance past the last chunk: it may
+        // still be the active write target and grow further.
+        if b.readChunk+1 >= len(b.chunks) {
+          break
+        }
+        b.readChunk++
+        b.readOff = 0
+        continue
+      }
+      seg := (*c.buf)[b.readOff:c.len]
+      if i := bytes.IndexByte(seg, delim); i >= 0 {
+        out = append(out, seg[:i+1]...)
+        b.readOff += i + 1
+        if b.isPipe {
+          b.reclaimConsumedChunksLocked()
+          b.cond.Broadcast()
+        }
+        return out, nil
+      }
+      out = append(out, seg...)
+      b.readOff = c.len
+    }
+    if b.isPipe {
+      b.reclaimConsumedChunksLocked()
+      if b.writeClosed {
+        if b.writeErr != nil {
+          return out, b.writeErr
+        }
+        return out, io.EOF
+      }
+      b.cond.Broadcast() // bytes were consumed, wake any blocked writer
+      b.cond.Wait()
+      continue
+    }
+    spillAvail := b.spillSize - b.spillOffset
+    if spillAvail <= 0 {
+      if len(out) == 0 {
+        return nil, io.EOF
+      }
+      return out, io.EOF
+    }
+    want := spillAvail
+    if want > readSize {
+      want = readSize
+    }
+    chunk := make([]byte, want)
+    m, e := b.spill.ReadAt(chunk, b.spillOffset)
+    seg := chunk[:m]
+    if i := bytes.IndexByte(seg, delim); i >= 0 {
+      out = append(out, seg[:i+1]...)
+      b.spillOffset += int64(i + 1)
+      return out, nil
+    }
+    out = append(out, seg...)
+    b.spillOffset += int64(m)
+    if e != nil && e != io.EOF {
+      return out, e
+    }
+  }
+}
+
+// ReadLine reads a line up to and including the next '\n', strips any
+// trailing '\n' and '\r', and returns it as a string. Like ReadBytes, it
+// returns the partial line read so far along with io.EOF when the buffer
+// is exhausted before a newline is found.
+func (b *MultiReadBuffer) ReadLine() (string, error) {
+  line, err := b.ReadBytes('\n')
+  if n := len(line); n > 0 && line[n-1] == '\n' {
+    line = line[:n-1]
+  }
+  if n := len(line); n > 0 && line[n-1] == '\r' {
+    line = line[:n-1]
+  }
+  return string(line), err
+}
+
+// Scanner returns a bufio.Scanner reading from the buffer's own read
+// cursor, using split if non-nil or bufio.ScanLines otherwise.
+func (b *MultiReadBuffer) Scanner(split bufio.SplitFunc) *bufio.Scanner {
+  s := bufio.NewScanner(b)
+  if split != nil {
+    s.Split(split)
+  }
+  return s
 }
 
 func (b *MultiReadBuffer) ReadFrom(r io.Reader) (n int64, err error) {
@@ -152,26 +750,194 @@ func (b *MultiReadBuffer) ReadFrom(r io.Reader) (n int64, err error) {
 	}
 }
 
+// memBuffersLocked gathers the unread tail of every remaining chunk into a
+// net.Buffers, without copying, and advances the read cursor past them. The
+// last chunk is left in place (with readOff at its current end) rather than
+// advanced past, since it may still be the active write target and grow
+// further. Assumes the caller holds the lock.
+func (b *MultiReadBuffer) memBuffersLocked() net.Buffers {
+  var buffers net.Buffers
+  for b.readChunk < len(b.chunks) {
+    c := b.chunks[b.readChunk]
+    if b.readOff < c.len {
+      buffers = append(buffers, (*c.buf)[b.readOff:c.len])
+    }
+    if b.readChunk+1 >= len(b.chunks) {
+      b.readOff = c.len
+      break
+    }
+    b.readChunk++
+    b.readOff = 0
+  }
+  return buffers
+}
+
+// WriteTo drains the buffer (in-memory head, then spilled tail) into w. The
+// in-memory head is gathered into a net.Buffers and written with a single
+// WriteTo call, which uses writev to avoid concatenating chunks when w
+// supports it.
 func (b *MultiReadBuffer) WriteTo(w io.Writer) (n int64, err error) {
   b.mu.Lock()
-	defer b.mu.Unlock()
-	if nBytes := len(b.buf) - b.offset; nBytes > 0 {
-		m, e := w.Write(b.buf[b.offset:])
-		if m > nBytes {
-			return n, fmt.Errorf("WriteTo: invalid Write count %d, should be %d", m, nBytes)
-		}
-		b.offset += m
-		n = int64(m)
-		if e != nil {
-			return n, e
-		}
-		// all bytes should have been written, by definition of
-		// Write method in io.Writer
-		if m != nBytes {
-			return n, io.ErrShortWrite
-		}
+  buffers := b.memBuffersLocked()
+  b.mu.Unlock()
+  if len(buffers) > 0 {
+    nn, ew := buffers.WriteTo(w)
+    n += nn
+    if b.isPipe {
+      // Only reclaim (returning chunk backing arrays to chunkPool) now
+      // that the write above is done with them; doing it before the
+      // unlocked WriteTo call would let a concurrent Write reuse a chunk
+      // still being read out.
+      b.mu.Lock()
+      b.reclaimConsumedChunksLocked()
+      b.cond.Broadcast() // memory just freed up, wake any blocked writer
+      b.mu.Unlock()
+    }
+    if ew != nil {
+      return n, ew
+    }
+  } else if b.isPipe {
+    b.mu.Lock()
+    b.cond.Broadcast()
+    b.mu.Unlock()
+  }
+  buf := make([]byte, readSize)
+	for {
+    nr, er := b.Read(buf) // this will lock; memory side is already drained
+    if nr > 0 {
+      nw, ew := w.Write(buf[:nr])
+      if nw > nr {
+        return n, fmt.Errorf("WriteTo: invalid Write count %d, should be %d", nw, nr)
+      }
+      n += int64(nw)
+      if ew != nil {
+        return n, ew
+      }
+      // all bytes should have been written, by definition of
+      // Write method in io.Writer
+      if nw != nr {
+        return n, io.ErrShortWrite
+      }
+    }
+    if er == io.EOF {
+      return n, nil
+    } else if er != nil {
+      return n, er
+    }
 	}
-	return n, nil
+}
+
+// readAt reads len(p) bytes starting at the absolute byte offset pos,
+// without disturbing the buffer's own read cursor or spillOffset. It
+// backs the independent cursors returned by NewReader.
+func (b *MultiReadBuffer) readAt(pos int64, p []byte) (n int, err error) {
+  b.mu.Lock()
+	defer b.mu.Unlock()
+  if pos < b.reclaimedBytes {
+    return 0, fmt.Errorf("multiReadBuffer: position %d no longer available (reclaimed)", pos)
+  }
+  pos -= b.reclaimedBytes
+  if pos < b.memLen {
+    idx, off := 0, pos
+    for idx < len(b.chunks) && off >= int64(b.chunks[idx].len) {
+      off -= int64(b.chunks[idx].len)
+      idx++
+    }
+    for n < len(p) && idx < len(b.chunks) {
+      c := b.chunks[idx]
+      if off >= int64(c.len) {
+        idx++
+        off = 0
+        continue
+      }
+      m := copy(p[n:], (*c.buf)[off:c.len])
+      n += m
+      off += int64(m)
+    }
+    pos += int64(n)
+  }
+  if n < len(p) {
+    if spillPos := pos - b.memLen; spillPos >= 0 {
+      if avail := b.spillSize - spillPos; avail > 0 {
+        want := int64(len(p) - n)
+        if want > avail {
+          want = avail
+        }
+        m, e := b.spill.ReadAt(p[n:n+int(want)], spillPos)
+        n += m
+        if e != nil && e != io.EOF {
+          return n, e
+        }
+      }
+    }
+  }
+  if n == 0 {
+    return 0, io.EOF
+  }
+  return n, nil
+}
+
+// multiReader is an independent read cursor over a MultiReadBuffer's
+// content, returned by NewReader. Multiple readers (and the buffer's own
+// cursor) can coexist and drain the same bytes at different positions.
+type multiReader struct {
+  b *MultiReadBuffer
+  mu sync.Mutex
+  pos int64
+  closed bool
+}
+
+// NewReader returns an independent cursor over the buffer's content. It
+// shares the underlying bytes with b and with any other reader created
+// this way, but advances its own position without disturbing b's write
+// position or its own Read/WriteTo cursor.
+func (b *MultiReadBuffer) NewReader() io.ReadSeekCloser {
+  return &multiReader{b: b}
+}
+
+func (r *multiReader) Read(p []byte) (int, error) {
+  r.mu.Lock()
+	defer r.mu.Unlock()
+  if r.closed {
+    return 0, os.ErrClosed
+  }
+  n, err := r.b.readAt(r.pos, p)
+  r.pos += int64(n)
+  return n, err
+}
+
+func (r *multiReader) Seek(offset int64, whence int) (int64, error) {
+  r.mu.Lock()
+	defer r.mu.Unlock()
+  if r.closed {
+    return 0, os.ErrClosed
+  }
+  size := r.b.Size()
+  var abs int64
+  switch whence {
+  case io.SeekStart:
+    abs = offset
+  case io.SeekCurrent:
+    abs = r.pos + offset
+  case io.SeekEnd:
+    abs = size + offset
+  default:
+    return 0, fmt.Errorf("multiReader.Seek: invalid whence %d", whence)
+  }
+  if abs < 0 {
+    return 0, fmt.Errorf("multiReader.Seek: negative position %d", abs)
+  }
+  r.pos = abs
+  return abs, nil
+}
+
+// Close marks the reader closed. It is idempotent and never returns an
+// error; the underlying buffer and its bytes are unaffected.
+func (r *multiReader) Close() error {
+  r.mu.Lock()
+	defer r.mu.Unlock()
+  r.closed = true
+  return nil
 }
 
 func main() {
@@ -199,4 +965,4 @@ func main() {
   b.Reset()
   n, err = b.WriteTo(&b2)
   fmt.Printf("b write to b2: %d, %q, err: %v\n", n, string(b2.Bytes()), err)
-}
\ No newline at end of file
+}